@@ -0,0 +1,170 @@
+package gm
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	//BlockTypeSM2PrivateKey armor 区块类型：SM2 私钥
+	BlockTypeSM2PrivateKey = "SM2 PRIVATE KEY"
+	//BlockTypeSM2PublicKey armor 区块类型：SM2 公钥
+	BlockTypeSM2PublicKey = "SM2 PUBLIC KEY"
+	//BlockTypeSM2Signature armor 区块类型：SM2 签名
+	BlockTypeSM2Signature = "SM2 SIGNATURE"
+
+	armorEncryptedHeader = "encrypted"
+
+	armorLineWidth = 64
+	crc24Init      = 0xB704CE
+	crc24Poly      = 0x1864CFB
+	crc24Mask      = 0xFFFFFF
+)
+
+func crc24(data []byte) uint32 {
+	crc := uint32(crc24Init)
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= crc24Poly
+			}
+		}
+	}
+	return crc & crc24Mask
+}
+
+//ArmorEncode 将 data 以 PEM 风格的文本装甲格式编码，附带 headers 与 CRC24 校验行
+func ArmorEncode(blockType string, headers map[string]string, data []byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "-----BEGIN %s-----\n", blockType)
+
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s: %s\n", k, headers[k])
+	}
+	if len(headers) > 0 {
+		b.WriteString("\n")
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		n := armorLineWidth
+		if n > len(encoded) {
+			n = len(encoded)
+		}
+		b.WriteString(encoded[:n])
+		b.WriteString("\n")
+		encoded = encoded[n:]
+	}
+
+	crc := crc24(data)
+	crcBytes := []byte{byte(crc >> 16), byte(crc >> 8), byte(crc)}
+	fmt.Fprintf(&b, "=%s\n", base64.StdEncoding.EncodeToString(crcBytes))
+	fmt.Fprintf(&b, "-----END %s-----\n", blockType)
+
+	return b.String()
+}
+
+//ArmorDecode 解析 ArmorEncode 产生的文本，校验 CRC24 并返回区块类型、headers 与原始数据
+func ArmorDecode(s string) (blockType string, headers map[string]string, data []byte, err error) {
+	scanner := bufio.NewScanner(strings.NewReader(strings.TrimSpace(s)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	if !scanner.Scan() {
+		return "", nil, nil, fmt.Errorf("gm: empty armor input")
+	}
+	beginLine := strings.TrimSpace(scanner.Text())
+	if !strings.HasPrefix(beginLine, "-----BEGIN ") || !strings.HasSuffix(beginLine, "-----") {
+		return "", nil, nil, fmt.Errorf("gm: missing armor BEGIN line")
+	}
+	blockType = strings.TrimSuffix(strings.TrimPrefix(beginLine, "-----BEGIN "), "-----")
+
+	headers = make(map[string]string)
+	var bodyLines []string
+	var crcLine string
+	inBody := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "-----END ") {
+			break
+		}
+		if line == "" {
+			inBody = true
+			continue
+		}
+		if !inBody {
+			parts := strings.SplitN(line, ":", 2)
+			if len(parts) != 2 {
+				inBody = true
+			} else {
+				headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+				continue
+			}
+		}
+		if strings.HasPrefix(line, "=") {
+			crcLine = line[1:]
+			continue
+		}
+		bodyLines = append(bodyLines, line)
+	}
+
+	data, err = base64.StdEncoding.DecodeString(strings.Join(bodyLines, ""))
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("gm: invalid armor base64 body: %v", err)
+	}
+
+	if crcLine != "" {
+		crcBytes, err := base64.StdEncoding.DecodeString(crcLine)
+		if err != nil || len(crcBytes) != 3 {
+			return "", nil, nil, fmt.Errorf("gm: invalid armor checksum line")
+		}
+		want := uint32(crcBytes[0])<<16 | uint32(crcBytes[1])<<8 | uint32(crcBytes[2])
+		if crc24(data) != want {
+			return "", nil, nil, fmt.Errorf("gm: armor CRC24 checksum mismatch")
+		}
+	}
+
+	return blockType, headers, data, nil
+}
+
+//ArmorPrivateKey 对私钥进行文本装甲编码；若提供 passphrase，先通过 keystore 加密再装甲
+func ArmorPrivateKey(priv []byte, passphrase string) (string, error) {
+	headers := map[string]string{}
+	payload := priv
+
+	if passphrase != "" {
+		ks, err := EncryptToKeystore(priv, passphrase, KeystoreOpts{})
+		if err != nil {
+			return "", err
+		}
+		payload = ks
+		headers[armorEncryptedHeader] = "true"
+	}
+
+	return ArmorEncode(BlockTypeSM2PrivateKey, headers, payload), nil
+}
+
+//UnarmorPrivateKey 解析 ArmorPrivateKey 产生的文本，若标记为加密则使用 passphrase 解密
+func UnarmorPrivateKey(s, passphrase string) ([]byte, error) {
+	blockType, headers, data, err := ArmorDecode(s)
+	if err != nil {
+		return nil, err
+	}
+	if blockType != BlockTypeSM2PrivateKey {
+		return nil, fmt.Errorf("gm: expected armor block type %q, got %q", BlockTypeSM2PrivateKey, blockType)
+	}
+
+	if headers[armorEncryptedHeader] == "true" {
+		return DecryptFromKeystore(data, passphrase)
+	}
+	return data, nil
+}
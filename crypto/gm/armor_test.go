@@ -0,0 +1,84 @@
+package gm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestArmorEncodeDecodeRoundTrip(t *testing.T) {
+	data := []byte{0x01, 0x02, 0x03, 0xFF, 0x00, 0xAB}
+	headers := map[string]string{"version": "1"}
+
+	armored := ArmorEncode(BlockTypeSM2PublicKey, headers, data)
+
+	blockType, gotHeaders, gotData, err := ArmorDecode(armored)
+	if err != nil {
+		t.Fatalf("ArmorDecode failed: %v", err)
+	}
+	if blockType != BlockTypeSM2PublicKey {
+		t.Fatalf("block type mismatch: got %q", blockType)
+	}
+	if gotHeaders["version"] != "1" {
+		t.Fatalf("header not round-tripped: %v", gotHeaders)
+	}
+	if !bytes.Equal(gotData, data) {
+		t.Fatalf("data not round-tripped: got %x, want %x", gotData, data)
+	}
+}
+
+func TestArmorDecodeDetectsCorruption(t *testing.T) {
+	armored := ArmorEncode(BlockTypeSM2Signature, nil, []byte("some signature bytes"))
+
+	// Flip a byte inside the base64 body without touching the checksum line.
+	lines := strings.Split(armored, "\n")
+	for i, line := range lines {
+		if line != "" && !strings.HasPrefix(line, "-----") && !strings.HasPrefix(line, "=") {
+			lines[i] = "Z" + line[1:]
+			break
+		}
+	}
+	corrupted := strings.Join(lines, "\n")
+
+	if _, _, _, err := ArmorDecode(corrupted); err == nil {
+		t.Fatalf("expected ArmorDecode to detect CRC24 mismatch on corrupted body")
+	}
+}
+
+func TestArmorPrivateKeyWithoutPassphrase(t *testing.T) {
+	priv, _ := GenerateKey()
+
+	armored, err := ArmorPrivateKey(priv, "")
+	if err != nil {
+		t.Fatalf("ArmorPrivateKey failed: %v", err)
+	}
+
+	got, err := UnarmorPrivateKey(armored, "")
+	if err != nil {
+		t.Fatalf("UnarmorPrivateKey failed: %v", err)
+	}
+	if !bytes.Equal(got, priv) {
+		t.Fatalf("unarmored private key does not match original")
+	}
+}
+
+func TestArmorPrivateKeyWithPassphrase(t *testing.T) {
+	priv, _ := GenerateKey()
+
+	armored, err := ArmorPrivateKey(priv, "hunter2")
+	if err != nil {
+		t.Fatalf("ArmorPrivateKey failed: %v", err)
+	}
+
+	if _, err := UnarmorPrivateKey(armored, "wrong"); err == nil {
+		t.Fatalf("expected UnarmorPrivateKey to fail with the wrong passphrase")
+	}
+
+	got, err := UnarmorPrivateKey(armored, "hunter2")
+	if err != nil {
+		t.Fatalf("UnarmorPrivateKey failed: %v", err)
+	}
+	if !bytes.Equal(got, priv) {
+		t.Fatalf("unarmored private key does not match original")
+	}
+}
@@ -0,0 +1,152 @@
+package gm
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+//CipherMode SM2EncryptWithMode/SM2DecryptWithMode 使用的密文编码方式
+type CipherMode int
+
+const (
+	ModeRawC1C3C2  CipherMode = iota // 原始 C1C3C2 拼接，当前默认格式
+	ModeRawC1C2C3                    // 原始 C1C2C3 拼接，兼容历史实现
+	ModeASN1C1C3C2                   // GM/T 0009-2012 ASN.1 DER 封装，hash/cipher 为 C1C3C2 顺序
+	ModeASN1C1C2C3                   // 同上 ASN.1 封装，hash/cipher 为 C1C2C3 顺序
+)
+
+const (
+	c1UncompressedLength = 65 // 0x04 || X(32) || Y(32)
+	c3Length             = 32 // SM3 digest
+)
+
+//sm2CipherASN1 GM/T 0009-2012 的 SEQUENCE{ x, y, hash, cipher }
+type sm2CipherASN1 struct {
+	X      *big.Int
+	Y      *big.Int
+	Hash   []byte
+	Cipher []byte
+}
+
+//splitRawC1C3C2 拆分 sm2.Encrypt 产生的原始 C1||C3||C2 密文
+func splitRawC1C3C2(data []byte) (c1, c3, c2 []byte, err error) {
+	if len(data) < c1UncompressedLength+c3Length {
+		return nil, nil, nil, fmt.Errorf("gm: ciphertext too short")
+	}
+	c1 = data[:c1UncompressedLength]
+	c3 = data[c1UncompressedLength : c1UncompressedLength+c3Length]
+	c2 = data[c1UncompressedLength+c3Length:]
+	return c1, c3, c2, nil
+}
+
+//splitRawC1C2C3 拆分原始 C1||C2||C3 密文；C2 是变长的，因此 C3 取自末尾 32 字节而非固定偏移
+func splitRawC1C2C3(data []byte) (c1, c2, c3 []byte, err error) {
+	if len(data) < c1UncompressedLength+c3Length {
+		return nil, nil, nil, fmt.Errorf("gm: ciphertext too short")
+	}
+	c1 = data[:c1UncompressedLength]
+	c2 = data[c1UncompressedLength : len(data)-c3Length]
+	c3 = data[len(data)-c3Length:]
+	return c1, c2, c3, nil
+}
+
+//MarshalSM2Cipher 将 tjfoc sm2.Encrypt 产生的原始 C1||C3||C2 密文转换为 mode 指定的线上格式
+func MarshalSM2Cipher(raw []byte, mode CipherMode) ([]byte, error) {
+	c1, c3, c2, err := splitRawC1C3C2(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	x := new(big.Int).SetBytes(c1[1:33])
+	y := new(big.Int).SetBytes(c1[33:65])
+
+	switch mode {
+	case ModeRawC1C3C2:
+		return raw, nil
+	case ModeRawC1C2C3:
+		out := make([]byte, 0, len(raw))
+		out = append(out, c1...)
+		out = append(out, c2...)
+		out = append(out, c3...)
+		return out, nil
+	case ModeASN1C1C3C2:
+		return asn1.Marshal(sm2CipherASN1{X: x, Y: y, Hash: c3, Cipher: c2})
+	case ModeASN1C1C2C3:
+		return asn1.Marshal(sm2CipherASN1{X: x, Y: y, Hash: c2, Cipher: c3})
+	default:
+		return nil, fmt.Errorf("gm: unknown cipher mode %d", mode)
+	}
+}
+
+//UnmarshalSM2Cipher 将 mode 编码的密文还原为 sm2.Decrypt 所需的原始 C1||C3||C2 形式
+func UnmarshalSM2Cipher(data []byte, mode CipherMode) ([]byte, error) {
+	switch mode {
+	case ModeRawC1C3C2:
+		return data, nil
+	case ModeRawC1C2C3:
+		c1, c2, c3, err := splitRawC1C2C3(data)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, 0, len(data))
+		out = append(out, c1...)
+		out = append(out, c3...)
+		out = append(out, c2...)
+		return out, nil
+	case ModeASN1C1C3C2, ModeASN1C1C2C3:
+		var parsed sm2CipherASN1
+		if _, err := asn1.Unmarshal(data, &parsed); err != nil {
+			return nil, fmt.Errorf("gm: unmarshal ASN.1 cipher failed: %v", err)
+		}
+
+		c1 := make([]byte, c1UncompressedLength)
+		c1[0] = 0x04
+		xb, yb := parsed.X.Bytes(), parsed.Y.Bytes()
+		copy(c1[1+32-len(xb):33], xb)
+		copy(c1[33+32-len(yb):65], yb)
+
+		var c3, c2 []byte
+		if mode == ModeASN1C1C3C2 {
+			c3, c2 = parsed.Hash, parsed.Cipher
+		} else {
+			c2, c3 = parsed.Hash, parsed.Cipher
+		}
+
+		raw := make([]byte, 0, len(c1)+len(c3)+len(c2))
+		raw = append(raw, c1...)
+		raw = append(raw, c3...)
+		raw = append(raw, c2...)
+		return raw, nil
+	default:
+		return nil, fmt.Errorf("gm: unknown cipher mode %d", mode)
+	}
+}
+
+//SM2EncryptWithMode 使用 pub 加密 data，并按 mode 指定的 CipherMode 编码密文
+func SM2EncryptWithMode(publicKey []byte, data []byte, mode CipherMode) ([]byte, error) {
+	pub, err := parsePubKeySafe(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := sm2.Encrypt(pub, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return MarshalSM2Cipher(raw, mode)
+}
+
+//SM2DecryptWithMode 按给定 CipherMode 解码密文，再用 privateKey 解密
+func SM2DecryptWithMode(privateKey []byte, data []byte, mode CipherMode) ([]byte, error) {
+	raw, err := UnmarshalSM2Cipher(data, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	priv, _ := PrivKeyFromBytes(sm2.P256Sm2(), privateKey)
+	return sm2.Decrypt(priv, raw)
+}
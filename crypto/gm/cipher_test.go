@@ -0,0 +1,76 @@
+package gm
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSM2EncryptDecryptWithModeRoundTrip(t *testing.T) {
+	priv, pub := GenerateKey()
+
+	modes := []CipherMode{ModeRawC1C3C2, ModeRawC1C2C3, ModeASN1C1C3C2, ModeASN1C1C2C3}
+	lengths := []int{1, 31, 32, 33, 55}
+
+	for _, mode := range modes {
+		for _, n := range lengths {
+			msg := bytes.Repeat([]byte{0xAB}, n)
+
+			ct, err := SM2EncryptWithMode(pub, msg, mode)
+			if err != nil {
+				t.Fatalf("mode %d len %d: encrypt failed: %v", mode, n, err)
+			}
+
+			pt, err := SM2DecryptWithMode(priv, ct, mode)
+			if err != nil {
+				t.Fatalf("mode %d len %d: decrypt failed: %v", mode, n, err)
+			}
+
+			if !bytes.Equal(pt, msg) {
+				t.Fatalf("mode %d len %d: round trip mismatch", mode, n)
+			}
+		}
+	}
+}
+
+func TestSM2EncryptDefaultsToRawC1C3C2(t *testing.T) {
+	priv, pub := GenerateKey()
+	msg := []byte("default mode message")
+
+	ct, err := SM2Encrypt(pub, msg)
+	if err != nil {
+		t.Fatalf("SM2Encrypt failed: %v", err)
+	}
+
+	pt, err := SM2DecryptWithMode(priv, ct, ModeRawC1C3C2)
+	if err != nil {
+		t.Fatalf("SM2DecryptWithMode failed: %v", err)
+	}
+	if !bytes.Equal(pt, msg) {
+		t.Fatalf("SM2Encrypt did not produce ModeRawC1C3C2 ciphertext")
+	}
+
+	pt2, err := SM2Decrypt(priv, ct)
+	if err != nil || !bytes.Equal(pt2, msg) {
+		t.Fatalf("SM2Decrypt round trip failed: %v", err)
+	}
+}
+
+func TestSM2EncryptWithModeRejectsMalformedPublicKey(t *testing.T) {
+	if _, err := SM2EncryptWithMode(nil, []byte("x"), ModeRawC1C3C2); err == nil {
+		t.Fatalf("expected error for nil public key")
+	}
+	if _, err := SM2EncryptWithMode([]byte{0x02, 0x01}, []byte("x"), ModeRawC1C3C2); err == nil {
+		t.Fatalf("expected error for short public key")
+	}
+}
+
+func TestUnmarshalSM2CipherRejectsTruncatedInput(t *testing.T) {
+	// ModeRawC1C3C2 is a straight passthrough (sm2.Decrypt validates it),
+	// so only the modes that actually parse the input here are checked.
+	if _, err := UnmarshalSM2Cipher([]byte("too short"), ModeRawC1C2C3); err == nil {
+		t.Fatalf("expected error for truncated raw ciphertext")
+	}
+	if _, err := UnmarshalSM2Cipher([]byte("not valid asn1"), ModeASN1C1C3C2); err == nil {
+		t.Fatalf("expected error for invalid ASN.1 ciphertext")
+	}
+}
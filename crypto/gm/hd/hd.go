@@ -0,0 +1,137 @@
+//Package hd 实现基于 SM2 P-256 曲线的 BIP32 风格分层确定性密钥派生
+package hd
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/yann-sjtu/chain33-sdk-go/crypto/gm"
+)
+
+const (
+	masterKeySalt = "SM2 seed" // 派生主密钥时使用的 HMAC key，类比 bitcoin 的 "Bitcoin seed"
+
+	//HardenedOffset 标记索引为 hardened，沿用 BIP32 的约定
+	HardenedOffset uint32 = 0x80000000
+)
+
+var curveOrder = sm2.P256Sm2().Params().N
+
+//NewMasterKey 由 seed 派生主私钥与链码：I = HMAC-SHA512("SM2 seed", seed) 拆分为 IL||IR，
+//IL 为 0 或 >= 曲线阶时在 seed 前加 0x01 重试，直至得到合法主密钥
+func NewMasterKey(seed []byte) (priv [32]byte, chainCode [32]byte) {
+	data := seed
+	for {
+		mac := hmac.New(sha512.New, []byte(masterKeySalt))
+		mac.Write(data)
+		i := mac.Sum(nil)
+
+		il, ir := i[:32], i[32:]
+		ilInt := new(big.Int).SetBytes(il)
+		if ilInt.Sign() != 0 && ilInt.Cmp(curveOrder) < 0 {
+			copy(priv[:], il)
+			copy(chainCode[:], ir)
+			return priv, chainCode
+		}
+
+		data = append([]byte{0x01}, data...)
+	}
+}
+
+//ser32 按 BIP32 约定将 uint32 序列化为 4 字节大端值
+func ser32(i uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, i)
+	return b
+}
+
+//DerivePrivateKey 由父私钥、链码及 index 派生子私钥与子链码；hardened 子密钥(index |= HardenedOffset)
+//基于父私钥派生，普通子密钥基于父公钥的压缩形式派生
+func DerivePrivateKey(parentPriv, parentChainCode [32]byte, index uint32, hardened bool) (childPriv [32]byte, childChainCode [32]byte, err error) {
+	if hardened {
+		index |= HardenedOffset
+	}
+
+	var data []byte
+	if index >= HardenedOffset {
+		data = append([]byte{0x00}, parentPriv[:]...)
+	} else {
+		_, pub := gm.PrivKeyFromBytes(sm2.P256Sm2(), parentPriv[:])
+		data = gm.SerializePublicKey(pub)
+	}
+	data = append(data, ser32(index)...)
+
+	mac := hmac.New(sha512.New, parentChainCode[:])
+	mac.Write(data)
+	i := mac.Sum(nil)
+
+	il, ir := i[:32], i[32:]
+	ilInt := new(big.Int).SetBytes(il)
+	if ilInt.Cmp(curveOrder) >= 0 {
+		return childPriv, childChainCode, fmt.Errorf("hd: invalid child, IL >= curve order")
+	}
+
+	childInt := new(big.Int).Add(ilInt, new(big.Int).SetBytes(parentPriv[:]))
+	childInt.Mod(childInt, curveOrder)
+	if childInt.Sign() == 0 {
+		return childPriv, childChainCode, fmt.Errorf("hd: invalid child, derived key is zero")
+	}
+
+	b := childInt.Bytes()
+	copy(childPriv[32-len(b):], b)
+	copy(childChainCode[:], ir)
+	return childPriv, childChainCode, nil
+}
+
+//DerivePath 从 seed 出发，沿 BIP44 风格路径(如 "m/44'/X'/0'/0/i")逐级派生，返回最终私钥与链码
+func DerivePath(seed []byte, path string) (priv [32]byte, chainCode [32]byte, err error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return priv, chainCode, err
+	}
+
+	priv, chainCode = NewMasterKey(seed)
+	for _, seg := range segments {
+		priv, chainCode, err = DerivePrivateKey(priv, chainCode, seg.index, seg.hardened)
+		if err != nil {
+			return priv, chainCode, err
+		}
+	}
+	return priv, chainCode, nil
+}
+
+type pathSegment struct {
+	index    uint32
+	hardened bool
+}
+
+//parsePath 解析形如 "m/44'/X'/0'/0/i" 的派生路径，识别 "'" 与 "h"/"H" 两种 hardened 标记
+func parsePath(path string) ([]pathSegment, error) {
+	parts := strings.Split(path, "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, fmt.Errorf("hd: path must start with \"m/\", got %q", path)
+	}
+
+	segments := make([]pathSegment, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		hardened := false
+		if strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h") || strings.HasSuffix(part, "H") {
+			hardened = true
+			part = part[:len(part)-1]
+		}
+
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("hd: invalid path segment %q: %v", part, err)
+		}
+
+		segments = append(segments, pathSegment{index: uint32(index), hardened: hardened})
+	}
+	return segments, nil
+}
@@ -0,0 +1,95 @@
+package hd
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewMasterKeyDeterministic(t *testing.T) {
+	seed := []byte("correct horse battery staple")
+
+	priv1, cc1 := NewMasterKey(seed)
+	priv2, cc2 := NewMasterKey(seed)
+
+	if priv1 != priv2 || cc1 != cc2 {
+		t.Fatalf("NewMasterKey is not deterministic for the same seed")
+	}
+
+	otherPriv, _ := NewMasterKey([]byte("a different seed"))
+	if priv1 == otherPriv {
+		t.Fatalf("different seeds produced the same master key")
+	}
+}
+
+func TestDerivePrivateKeyHardenedAndNormal(t *testing.T) {
+	priv, cc := NewMasterKey([]byte("seed for derivation test"))
+
+	hardenedChild, hardenedCC, err := DerivePrivateKey(priv, cc, 0, true)
+	if err != nil {
+		t.Fatalf("hardened derivation failed: %v", err)
+	}
+
+	normalChild, normalCC, err := DerivePrivateKey(priv, cc, 0, false)
+	if err != nil {
+		t.Fatalf("normal derivation failed: %v", err)
+	}
+
+	if hardenedChild == normalChild {
+		t.Fatalf("hardened and normal children at the same index must differ")
+	}
+	if hardenedCC == normalCC {
+		t.Fatalf("hardened and normal children must have different chain codes")
+	}
+
+	again, _, err := DerivePrivateKey(priv, cc, 0, true)
+	if err != nil {
+		t.Fatalf("repeat hardened derivation failed: %v", err)
+	}
+	if !bytes.Equal(hardenedChild[:], again[:]) {
+		t.Fatalf("DerivePrivateKey is not deterministic")
+	}
+}
+
+func TestDerivePath(t *testing.T) {
+	seed := []byte("seed for path test")
+
+	priv, _, err := DerivePath(seed, "m/44'/0'/0'/0/0")
+	if err != nil {
+		t.Fatalf("DerivePath failed: %v", err)
+	}
+
+	masterPriv, masterCC := NewMasterKey(seed)
+	wantPriv, wantCC, err := DerivePrivateKey(masterPriv, masterCC, 44, true)
+	if err != nil {
+		t.Fatalf("manual derivation failed: %v", err)
+	}
+	wantPriv, wantCC, err = DerivePrivateKey(wantPriv, wantCC, 0, true)
+	if err != nil {
+		t.Fatalf("manual derivation failed: %v", err)
+	}
+	wantPriv, wantCC, err = DerivePrivateKey(wantPriv, wantCC, 0, true)
+	if err != nil {
+		t.Fatalf("manual derivation failed: %v", err)
+	}
+	wantPriv, wantCC, err = DerivePrivateKey(wantPriv, wantCC, 0, false)
+	if err != nil {
+		t.Fatalf("manual derivation failed: %v", err)
+	}
+	wantPriv, _, err = DerivePrivateKey(wantPriv, wantCC, 0, false)
+	if err != nil {
+		t.Fatalf("manual derivation failed: %v", err)
+	}
+
+	if priv != wantPriv {
+		t.Fatalf("DerivePath result does not match manual step-by-step derivation")
+	}
+}
+
+func TestDerivePathRejectsBadPath(t *testing.T) {
+	if _, _, err := DerivePath([]byte("seed"), "44'/0'/0'/0/0"); err == nil {
+		t.Fatalf("expected error for path missing the leading \"m\"")
+	}
+	if _, _, err := DerivePath([]byte("seed"), "m/not-a-number"); err == nil {
+		t.Fatalf("expected error for non-numeric path segment")
+	}
+}
@@ -0,0 +1,300 @@
+package gm
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tjfoc/gmsm/sm3"
+	"github.com/tjfoc/gmsm/sm4"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	keystoreVersion = 1
+
+	kdfPBKDF2SM3 = "pbkdf2-sm3"
+	kdfScrypt    = "scrypt"
+
+	defaultPBKDF2C      = 262144
+	defaultPBKDF2DKLen  = 32
+	defaultScryptN      = 1 << 18
+	defaultScryptR      = 8
+	defaultScryptP      = 1
+	defaultScryptDKLen  = 32
+	keystoreSaltLength  = 16
+	keystoreIVLength    = 16
+	keystoreCipherSM4   = "sm4-ctr"
+)
+
+//PBKDF2Params PBKDF2-SM3 KDF 参数
+type PBKDF2Params struct {
+	C     int
+	DKLen int
+}
+
+//ScryptParams scrypt KDF 参数
+type ScryptParams struct {
+	N     int
+	R     int
+	P     int
+	DKLen int
+}
+
+//KeystoreOpts 控制 EncryptToKeystore 使用的 KDF 及其参数
+type KeystoreOpts struct {
+	KDF    string // "pbkdf2-sm3" (default) or "scrypt"
+	PBKDF2 PBKDF2Params
+	Scrypt ScryptParams
+}
+
+type cipherParamsJSON struct {
+	IV string `json:"iv"`
+}
+
+type cryptoJSON struct {
+	Cipher       string                 `json:"cipher"`
+	CipherText   string                 `json:"ciphertext"`
+	CipherParams cipherParamsJSON       `json:"cipherparams"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+type keystoreJSON struct {
+	Version int        `json:"version"`
+	Address string     `json:"address"`
+	Crypto  cryptoJSON `json:"crypto"`
+}
+
+func sm3Sum(data []byte) []byte {
+	h := sm3.New()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// minDerivedKeyLength is the minimum KDF output length: 16 bytes feed the
+// SM4-CTR key and 16 bytes feed the MAC, per the EncryptToKeystore layout.
+const minDerivedKeyLength = 32
+
+func kdfParamString(params map[string]interface{}, key string) (string, error) {
+	v, ok := params[key].(string)
+	if !ok {
+		return "", fmt.Errorf("gm: keystore kdfparams missing or invalid %q", key)
+	}
+	return v, nil
+}
+
+func kdfParamInt(params map[string]interface{}, key string) (int, error) {
+	v, ok := params[key].(float64)
+	if !ok {
+		return 0, fmt.Errorf("gm: keystore kdfparams missing or invalid %q", key)
+	}
+	return int(v), nil
+}
+
+func deriveKeystoreKey(opts *cryptoJSON, passphrase string) ([]byte, error) {
+	saltHex, err := kdfParamString(opts.KDFParams, "salt")
+	if err != nil {
+		return nil, err
+	}
+	salt, err := hex.DecodeString(saltHex)
+	if err != nil {
+		return nil, fmt.Errorf("gm: invalid keystore salt: %v", err)
+	}
+
+	switch opts.KDF {
+	case kdfPBKDF2SM3:
+		c, err := kdfParamInt(opts.KDFParams, "c")
+		if err != nil {
+			return nil, err
+		}
+		dkLen, err := kdfParamInt(opts.KDFParams, "dklen")
+		if err != nil {
+			return nil, err
+		}
+		if dkLen < minDerivedKeyLength {
+			return nil, fmt.Errorf("gm: keystore dklen %d is below the required minimum of %d", dkLen, minDerivedKeyLength)
+		}
+		return pbkdf2.Key([]byte(passphrase), salt, c, dkLen, sm3.New), nil
+	case kdfScrypt:
+		n, err := kdfParamInt(opts.KDFParams, "n")
+		if err != nil {
+			return nil, err
+		}
+		r, err := kdfParamInt(opts.KDFParams, "r")
+		if err != nil {
+			return nil, err
+		}
+		p, err := kdfParamInt(opts.KDFParams, "p")
+		if err != nil {
+			return nil, err
+		}
+		dkLen, err := kdfParamInt(opts.KDFParams, "dklen")
+		if err != nil {
+			return nil, err
+		}
+		if dkLen < minDerivedKeyLength {
+			return nil, fmt.Errorf("gm: keystore dklen %d is below the required minimum of %d", dkLen, minDerivedKeyLength)
+		}
+		return scrypt.Key([]byte(passphrase), salt, n, r, p, dkLen)
+	default:
+		return nil, fmt.Errorf("gm: unsupported keystore kdf %q", opts.KDF)
+	}
+}
+
+func sm4CTRXor(key, iv, in []byte) ([]byte, error) {
+	block, err := sm4.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
+
+//EncryptToKeystore 使用口令加密 32 字节的 SM2 私钥，生成 Web3 风格的 JSON keystore
+func EncryptToKeystore(privKey []byte, passphrase string, opts KeystoreOpts) ([]byte, error) {
+	salt := getRandBytes(keystoreSaltLength)
+	iv := getRandBytes(keystoreIVLength)
+
+	kdf := opts.KDF
+	if kdf == "" {
+		kdf = kdfPBKDF2SM3
+	}
+
+	var derivedKey []byte
+	kdfParams := map[string]interface{}{"salt": hex.EncodeToString(salt)}
+
+	switch kdf {
+	case kdfPBKDF2SM3:
+		c := opts.PBKDF2.C
+		if c == 0 {
+			c = defaultPBKDF2C
+		}
+		dkLen := opts.PBKDF2.DKLen
+		if dkLen == 0 {
+			dkLen = defaultPBKDF2DKLen
+		}
+		if dkLen < minDerivedKeyLength {
+			return nil, fmt.Errorf("gm: keystore dklen %d is below the required minimum of %d", dkLen, minDerivedKeyLength)
+		}
+		derivedKey = pbkdf2.Key([]byte(passphrase), salt, c, dkLen, sm3.New)
+		kdfParams["c"] = c
+		kdfParams["dklen"] = dkLen
+		kdfParams["prf"] = "hmac-sm3"
+	case kdfScrypt:
+		n, r, p, dkLen := opts.Scrypt.N, opts.Scrypt.R, opts.Scrypt.P, opts.Scrypt.DKLen
+		if n == 0 {
+			n = defaultScryptN
+		}
+		if r == 0 {
+			r = defaultScryptR
+		}
+		if p == 0 {
+			p = defaultScryptP
+		}
+		if dkLen == 0 {
+			dkLen = defaultScryptDKLen
+		}
+		if dkLen < minDerivedKeyLength {
+			return nil, fmt.Errorf("gm: keystore dklen %d is below the required minimum of %d", dkLen, minDerivedKeyLength)
+		}
+		var err error
+		derivedKey, err = scrypt.Key([]byte(passphrase), salt, n, r, p, dkLen)
+		if err != nil {
+			return nil, fmt.Errorf("gm: scrypt kdf failed: %v", err)
+		}
+		kdfParams["n"] = n
+		kdfParams["r"] = r
+		kdfParams["p"] = p
+		kdfParams["dklen"] = dkLen
+	default:
+		return nil, fmt.Errorf("gm: unsupported keystore kdf %q", kdf)
+	}
+
+	ciphertext, err := sm4CTRXor(derivedKey[:16], iv, privKey)
+	if err != nil {
+		return nil, fmt.Errorf("gm: sm4-ctr encryption failed: %v", err)
+	}
+
+	mac := sm3Sum(append(append([]byte{}, derivedKey[16:32]...), ciphertext...))
+
+	ks := keystoreJSON{
+		Version: keystoreVersion,
+		Address: hex.EncodeToString(sm3Sum(PubKeyFromPrivate(privKey))),
+		Crypto: cryptoJSON{
+			Cipher:       keystoreCipherSM4,
+			CipherText:   hex.EncodeToString(ciphertext),
+			CipherParams: cipherParamsJSON{IV: hex.EncodeToString(iv)},
+			KDF:          kdf,
+			KDFParams:    kdfParams,
+			MAC:          hex.EncodeToString(mac),
+		},
+	}
+
+	return json.Marshal(ks)
+}
+
+//DecryptFromKeystore 使用口令解密 EncryptToKeystore 产生的 JSON keystore，返回 32 字节私钥
+func DecryptFromKeystore(jsonBlob []byte, passphrase string) ([]byte, error) {
+	var ks keystoreJSON
+	if err := json.Unmarshal(jsonBlob, &ks); err != nil {
+		return nil, fmt.Errorf("gm: invalid keystore json: %v", err)
+	}
+
+	derivedKey, err := deriveKeystoreKey(&ks.Crypto, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := hex.DecodeString(ks.Crypto.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("gm: invalid keystore ciphertext: %v", err)
+	}
+
+	wantMAC := sm3Sum(append(append([]byte{}, derivedKey[16:32]...), ciphertext...))
+	gotMAC, err := hex.DecodeString(ks.Crypto.MAC)
+	if err != nil || !bytes.Equal(wantMAC, gotMAC) {
+		return nil, fmt.Errorf("gm: keystore mac mismatch, wrong passphrase or corrupted file")
+	}
+
+	iv, err := hex.DecodeString(ks.Crypto.CipherParams.IV)
+	if err != nil {
+		return nil, fmt.Errorf("gm: invalid keystore iv: %v", err)
+	}
+
+	return sm4CTRXor(derivedKey[:16], iv, ciphertext)
+}
+
+//ValidateKeystore 仅重新计算 MAC 并比对，不做解密：JSON、KDF 参数或 hex 字段畸形时返回 error，
+//MAC 不符时返回 false；MAC 不符本身不区分口令错误与密文被篡改
+func ValidateKeystore(jsonBlob []byte, passphrase string) (bool, error) {
+	var ks keystoreJSON
+	if err := json.Unmarshal(jsonBlob, &ks); err != nil {
+		return false, fmt.Errorf("gm: invalid keystore json: %v", err)
+	}
+
+	derivedKey, err := deriveKeystoreKey(&ks.Crypto, passphrase)
+	if err != nil {
+		return false, err
+	}
+
+	ciphertext, err := hex.DecodeString(ks.Crypto.CipherText)
+	if err != nil {
+		return false, fmt.Errorf("gm: invalid keystore ciphertext: %v", err)
+	}
+
+	wantMAC := sm3Sum(append(append([]byte{}, derivedKey[16:32]...), ciphertext...))
+	gotMAC, err := hex.DecodeString(ks.Crypto.MAC)
+	if err != nil {
+		return false, fmt.Errorf("gm: invalid keystore mac: %v", err)
+	}
+
+	return bytes.Equal(wantMAC, gotMAC), nil
+}
+
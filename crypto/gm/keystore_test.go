@@ -0,0 +1,107 @@
+package gm
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestKeystorePBKDF2RoundTrip(t *testing.T) {
+	priv, _ := GenerateKey()
+
+	ks, err := EncryptToKeystore(priv, "correct horse", KeystoreOpts{})
+	if err != nil {
+		t.Fatalf("EncryptToKeystore failed: %v", err)
+	}
+
+	got, err := DecryptFromKeystore(ks, "correct horse")
+	if err != nil {
+		t.Fatalf("DecryptFromKeystore failed: %v", err)
+	}
+	if !bytes.Equal(got, priv) {
+		t.Fatalf("decrypted key does not match original")
+	}
+}
+
+func TestKeystoreScryptRoundTrip(t *testing.T) {
+	priv, _ := GenerateKey()
+
+	ks, err := EncryptToKeystore(priv, "correct horse", KeystoreOpts{
+		KDF:    "scrypt",
+		Scrypt: ScryptParams{N: 1 << 12, R: 8, P: 1, DKLen: 32},
+	})
+	if err != nil {
+		t.Fatalf("EncryptToKeystore with scrypt failed: %v", err)
+	}
+
+	got, err := DecryptFromKeystore(ks, "correct horse")
+	if err != nil {
+		t.Fatalf("DecryptFromKeystore failed: %v", err)
+	}
+	if !bytes.Equal(got, priv) {
+		t.Fatalf("decrypted key does not match original")
+	}
+}
+
+func TestKeystoreWrongPassphrase(t *testing.T) {
+	priv, _ := GenerateKey()
+	ks, err := EncryptToKeystore(priv, "correct horse", KeystoreOpts{})
+	if err != nil {
+		t.Fatalf("EncryptToKeystore failed: %v", err)
+	}
+
+	if _, err := DecryptFromKeystore(ks, "wrong passphrase"); err == nil {
+		t.Fatalf("expected decrypt to fail with the wrong passphrase")
+	}
+
+	ok, err := ValidateKeystore(ks, "wrong passphrase")
+	if err != nil {
+		t.Fatalf("ValidateKeystore unexpectedly errored: %v", err)
+	}
+	if ok {
+		t.Fatalf("expected ValidateKeystore to report false for the wrong passphrase")
+	}
+
+	ok, err = ValidateKeystore(ks, "correct horse")
+	if err != nil || !ok {
+		t.Fatalf("expected ValidateKeystore to report true for the correct passphrase, err=%v", err)
+	}
+}
+
+func TestKeystoreRejectsLowDKLen(t *testing.T) {
+	priv, _ := GenerateKey()
+
+	if _, err := EncryptToKeystore(priv, "x", KeystoreOpts{PBKDF2: PBKDF2Params{DKLen: 16}}); err == nil {
+		t.Fatalf("expected EncryptToKeystore to reject a dklen below 32")
+	}
+	if _, err := EncryptToKeystore(priv, "x", KeystoreOpts{KDF: "scrypt", Scrypt: ScryptParams{DKLen: 16}}); err == nil {
+		t.Fatalf("expected EncryptToKeystore to reject a scrypt dklen below 32")
+	}
+}
+
+func TestKeystoreMalformedKDFParamsDoesNotPanic(t *testing.T) {
+	priv, _ := GenerateKey()
+	ks, err := EncryptToKeystore(priv, "correct horse", KeystoreOpts{})
+	if err != nil {
+		t.Fatalf("EncryptToKeystore failed: %v", err)
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(ks, &m); err != nil {
+		t.Fatalf("unmarshal keystore failed: %v", err)
+	}
+	crypto := m["crypto"].(map[string]interface{})
+	kdfParams := crypto["kdfparams"].(map[string]interface{})
+	delete(kdfParams, "c")
+	corrupted, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("marshal corrupted keystore failed: %v", err)
+	}
+
+	if _, err := DecryptFromKeystore(corrupted, "correct horse"); err == nil {
+		t.Fatalf("expected DecryptFromKeystore to return an error for malformed kdfparams")
+	}
+	if _, err := ValidateKeystore(corrupted, "correct horse"); err == nil {
+		t.Fatalf("expected ValidateKeystore to return an error for malformed kdfparams")
+	}
+}
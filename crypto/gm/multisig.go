@@ -0,0 +1,215 @@
+package gm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+const multisigPubKeyLength = 33 // compressed SM2 public key
+
+//MultisigPubKey K-of-N SM2 门限公钥，保存参与方的压缩公钥与签名阈值
+type MultisigPubKey struct {
+	Threshold int
+	PubKeys   [][]byte // 33-byte compressed SM2 public keys, one per member
+}
+
+//NewMultisigPubKey 构造 K-of-N 门限公钥，threshold 必须介于 1 和成员数之间，且成员公钥不能重复
+//(否则同一签名者可在多个 bit 位下提交同一签名，伪造出 threshold 已满足的假象)
+func NewMultisigPubKey(threshold int, pubKeys [][]byte) (*MultisigPubKey, error) {
+	if threshold <= 0 || threshold > len(pubKeys) {
+		return nil, fmt.Errorf("gm: invalid threshold %d for %d members", threshold, len(pubKeys))
+	}
+	seen := make(map[string]int, len(pubKeys))
+	for i, pk := range pubKeys {
+		if len(pk) != multisigPubKeyLength {
+			return nil, fmt.Errorf("gm: member %d public key must be %d bytes, got %d", i, multisigPubKeyLength, len(pk))
+		}
+		if j, dup := seen[string(pk)]; dup {
+			return nil, fmt.Errorf("gm: member %d public key duplicates member %d", i, j)
+		}
+		seen[string(pk)] = i
+	}
+
+	return &MultisigPubKey{Threshold: threshold, PubKeys: pubKeys}, nil
+}
+
+//SerializeMultisigPubKey 序列化为 threshold(4 bytes) || count(4 bytes) || pubKeys...
+func SerializeMultisigPubKey(pub *MultisigPubKey) []byte {
+	out := make([]byte, 8, 8+len(pub.PubKeys)*multisigPubKeyLength)
+	binary.BigEndian.PutUint32(out[0:4], uint32(pub.Threshold))
+	binary.BigEndian.PutUint32(out[4:8], uint32(len(pub.PubKeys)))
+	for _, pk := range pub.PubKeys {
+		out = append(out, pk...)
+	}
+	return out
+}
+
+//ParseMultisigPubKey 解析 SerializeMultisigPubKey 产生的字节串
+func ParseMultisigPubKey(data []byte) (*MultisigPubKey, error) {
+	if len(data) < 8 {
+		return nil, fmt.Errorf("gm: multisig pubkey too short")
+	}
+
+	threshold := int(binary.BigEndian.Uint32(data[0:4]))
+	count := int(binary.BigEndian.Uint32(data[4:8]))
+	body := data[8:]
+	if len(body) != count*multisigPubKeyLength {
+		return nil, fmt.Errorf("gm: multisig pubkey length mismatch: expected %d members, got %d bytes", count, len(body))
+	}
+
+	pubKeys := make([][]byte, count)
+	for i := 0; i < count; i++ {
+		pk := make([]byte, multisigPubKeyLength)
+		copy(pk, body[i*multisigPubKeyLength:(i+1)*multisigPubKeyLength])
+		pubKeys[i] = pk
+	}
+
+	return NewMultisigPubKey(threshold, pubKeys)
+}
+
+//MultisigSignature 记录哪些成员签名(bitmap)及对应的 DER 签名
+type MultisigSignature struct {
+	Bitmap     []byte // one bit per member, LSB of Bitmap[0] is member 0
+	Signatures [][]byte
+}
+
+func bitmapSet(bitmap []byte, index int) {
+	bitmap[index/8] |= 1 << uint(index%8)
+}
+
+func bitmapIsSet(bitmap []byte, index int) bool {
+	return bitmap[index/8]&(1<<uint(index%8)) != 0
+}
+
+//Combine 将各签名者提交的部分签名(member index -> DER signature)合并为 MultisigSignature，
+//调用 SerializeMultisigSignature 即可得到可传输的字节串
+func Combine(numMembers int, partials map[int][]byte) (*MultisigSignature, error) {
+	bitmapLen := (numMembers + 7) / 8
+	sig := &MultisigSignature{Bitmap: make([]byte, bitmapLen)}
+
+	for index := 0; index < numMembers; index++ {
+		partial, ok := partials[index]
+		if !ok {
+			continue
+		}
+		bitmapSet(sig.Bitmap, index)
+		sig.Signatures = append(sig.Signatures, partial)
+	}
+
+	if len(sig.Signatures) == 0 {
+		return nil, fmt.Errorf("gm: no partial signatures to combine")
+	}
+	return sig, nil
+}
+
+//SerializeMultisigSignature 序列化为 bitmapLen(4 bytes) || bitmap || count(4 bytes) || (sigLen(4 bytes) || sig)...
+func SerializeMultisigSignature(sig *MultisigSignature) []byte {
+	size := 8 + len(sig.Bitmap) + 4
+	for _, s := range sig.Signatures {
+		size += 4 + len(s)
+	}
+
+	out := make([]byte, 0, size)
+
+	lenBuf := make([]byte, 4)
+	binary.BigEndian.PutUint32(lenBuf, uint32(len(sig.Bitmap)))
+	out = append(out, lenBuf...)
+	out = append(out, sig.Bitmap...)
+
+	binary.BigEndian.PutUint32(lenBuf, uint32(len(sig.Signatures)))
+	out = append(out, lenBuf...)
+	for _, s := range sig.Signatures {
+		binary.BigEndian.PutUint32(lenBuf, uint32(len(s)))
+		out = append(out, lenBuf...)
+		out = append(out, s...)
+	}
+
+	return out
+}
+
+//ParseMultisigSignature 解析 SerializeMultisigSignature 产生的字节串
+func ParseMultisigSignature(data []byte) (*MultisigSignature, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("gm: multisig signature too short")
+	}
+	bitmapLen := int(binary.BigEndian.Uint32(data[0:4]))
+	data = data[4:]
+	if len(data) < bitmapLen {
+		return nil, fmt.Errorf("gm: multisig signature bitmap truncated")
+	}
+	bitmap := make([]byte, bitmapLen)
+	copy(bitmap, data[:bitmapLen])
+	data = data[bitmapLen:]
+
+	if len(data) < 4 {
+		return nil, fmt.Errorf("gm: multisig signature missing count")
+	}
+	count := int(binary.BigEndian.Uint32(data[0:4]))
+	data = data[4:]
+
+	signatures := make([][]byte, count)
+	for i := 0; i < count; i++ {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("gm: multisig signature %d length truncated", i)
+		}
+		sigLen := int(binary.BigEndian.Uint32(data[0:4]))
+		data = data[4:]
+		if len(data) < sigLen {
+			return nil, fmt.Errorf("gm: multisig signature %d truncated", i)
+		}
+		sig := make([]byte, sigLen)
+		copy(sig, data[:sigLen])
+		data = data[sigLen:]
+		signatures[i] = sig
+	}
+
+	if len(data) != 0 {
+		return nil, fmt.Errorf("gm: multisig signature has trailing data")
+	}
+
+	return &MultisigSignature{Bitmap: bitmap, Signatures: signatures}, nil
+}
+
+//VerifyMultisig 验证至少 threshold 个成员对 msg 的签名均有效，且不存在重复使用的成员索引
+func VerifyMultisig(pub *MultisigPubKey, msg, uid []byte, sig *MultisigSignature) bool {
+	if uid == nil {
+		uid = DefaultUID
+	}
+
+	bitmapLen := (len(pub.PubKeys) + 7) / 8
+	if len(sig.Bitmap) != bitmapLen {
+		return false
+	}
+
+	valid := 0
+	sigIdx := 0
+	used := make(map[int]bool)
+	for member := 0; member < len(pub.PubKeys); member++ {
+		if !bitmapIsSet(sig.Bitmap, member) {
+			continue
+		}
+		if sigIdx >= len(sig.Signatures) {
+			return false
+		}
+		if used[member] {
+			return false
+		}
+		used[member] = true
+
+		r, s, err := DeserializeSignature(sig.Signatures[sigIdx])
+		sigIdx++
+		if err != nil {
+			return false
+		}
+
+		memberPub, err := parsePubKeySafe(pub.PubKeys[member])
+		if err != nil || !sm2.Sm2Verify(memberPub, msg, uid, r, s) {
+			return false
+		}
+		valid++
+	}
+
+	return sigIdx == len(sig.Signatures) && valid >= pub.Threshold
+}
@@ -0,0 +1,122 @@
+package gm
+
+import "testing"
+
+func TestMultisigThresholdVerify(t *testing.T) {
+	priv1, pub1 := GenerateKey()
+	priv2, pub2 := GenerateKey()
+	_, pub3 := GenerateKey()
+
+	msPub, err := NewMultisigPubKey(2, [][]byte{pub1, pub2, pub3})
+	if err != nil {
+		t.Fatalf("NewMultisigPubKey failed: %v", err)
+	}
+
+	msg := []byte("multisig message")
+	sig1, _ := SM2Sign(msg, priv1, nil)
+	sig2, _ := SM2Sign(msg, priv2, nil)
+
+	combined, err := Combine(3, map[int][]byte{0: sig1, 1: sig2})
+	if err != nil {
+		t.Fatalf("Combine failed: %v", err)
+	}
+
+	if !VerifyMultisig(msPub, msg, nil, combined) {
+		t.Fatalf("expected 2-of-3 multisig to verify with 2 valid signatures")
+	}
+}
+
+func TestMultisigBelowThresholdFails(t *testing.T) {
+	priv1, pub1 := GenerateKey()
+	_, pub2 := GenerateKey()
+	_, pub3 := GenerateKey()
+
+	msPub, err := NewMultisigPubKey(2, [][]byte{pub1, pub2, pub3})
+	if err != nil {
+		t.Fatalf("NewMultisigPubKey failed: %v", err)
+	}
+
+	msg := []byte("multisig message")
+	sig1, _ := SM2Sign(msg, priv1, nil)
+
+	combined, err := Combine(3, map[int][]byte{0: sig1})
+	if err != nil {
+		t.Fatalf("Combine failed: %v", err)
+	}
+
+	if VerifyMultisig(msPub, msg, nil, combined) {
+		t.Fatalf("expected 1-of-3 signatures to fail a 2-of-3 threshold")
+	}
+}
+
+// TestMultisigRejectsDuplicateMemberKeys guards against a single signer's
+// one valid signature being replayed under two bit positions of the same
+// physical key to fake a satisfied threshold.
+func TestMultisigRejectsDuplicateMemberKeys(t *testing.T) {
+	_, pub := GenerateKey()
+
+	if _, err := NewMultisigPubKey(2, [][]byte{pub, pub}); err == nil {
+		t.Fatalf("expected NewMultisigPubKey to reject duplicate member keys")
+	}
+
+	dup := SerializeMultisigPubKey(&MultisigPubKey{Threshold: 2, PubKeys: [][]byte{pub, pub}})
+	if _, err := ParseMultisigPubKey(dup); err == nil {
+		t.Fatalf("expected ParseMultisigPubKey to reject duplicate member keys")
+	}
+}
+
+func TestMultisigVerifyRejectsMalformedMemberKeyWithoutPanic(t *testing.T) {
+	_, pub := GenerateKey()
+	priv, _ := GenerateKey()
+	msPub, err := NewMultisigPubKey(1, [][]byte{pub})
+	if err != nil {
+		t.Fatalf("NewMultisigPubKey failed: %v", err)
+	}
+
+	msg := []byte("msg")
+	sig, _ := SM2Sign(msg, priv, nil)
+	combined, err := Combine(1, map[int][]byte{0: sig})
+	if err != nil {
+		t.Fatalf("Combine failed: %v", err)
+	}
+
+	// A member key whose X coordinate has no modular square root must make
+	// VerifyMultisig return false, never panic.
+	bad := make([]byte, multisigPubKeyLength)
+	bad[0] = 0x02
+	bad[len(bad)-1] = 0x02 // X = 2: x^3+ax+b is not a square mod p
+	msPub.PubKeys[0] = bad
+
+	if VerifyMultisig(msPub, msg, nil, combined) {
+		t.Fatalf("expected verification against a malformed member key to fail")
+	}
+}
+
+func TestMultisigSignatureSerializeRoundTrip(t *testing.T) {
+	priv1, pub1 := GenerateKey()
+	priv2, pub2 := GenerateKey()
+
+	msPub, err := NewMultisigPubKey(2, [][]byte{pub1, pub2})
+	if err != nil {
+		t.Fatalf("NewMultisigPubKey failed: %v", err)
+	}
+
+	msg := []byte("serialize me")
+	sig1, _ := SM2Sign(msg, priv1, nil)
+	sig2, _ := SM2Sign(msg, priv2, nil)
+
+	combined, err := Combine(2, map[int][]byte{0: sig1, 1: sig2})
+	if err != nil {
+		t.Fatalf("Combine failed: %v", err)
+	}
+
+	blob := SerializeMultisigSignature(combined)
+	parsed, err := ParseMultisigSignature(blob)
+	if err != nil {
+		t.Fatalf("ParseMultisigSignature failed: %v", err)
+	}
+
+	if !VerifyMultisig(msPub, msg, nil, parsed) {
+		t.Fatalf("expected round-tripped MultisigSignature to verify")
+	}
+}
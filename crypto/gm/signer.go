@@ -0,0 +1,135 @@
+package gm
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+const sm3DigestLength = 32
+
+func int2octets(v *big.Int) []byte {
+	b := make([]byte, sm3DigestLength)
+	vb := v.Bytes()
+	copy(b[len(b)-len(vb):], vb)
+	return b
+}
+
+// bits2octets implements RFC 6979 §2.3.4 for a hash the same bit length as
+// the SM2 curve order, so bits2int is a no-op truncation.
+func bits2octets(e []byte, n *big.Int) []byte {
+	z := new(big.Int).SetBytes(e)
+	z.Mod(z, n)
+	return int2octets(z)
+}
+
+func hmacSM3(key, data []byte) []byte {
+	mac := hmac.New(sm3.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+//SM2SignDeterministic 按 RFC 6979 的方式从 (priv, e) 派生签名随机数 k，避免依赖 crypto/rand，
+//从而防止低熵或故障注入环境下同一消息的两次签名泄露私钥
+func SM2SignDeterministic(msg, priv, uid []byte) ([]byte, error) {
+	if uid == nil {
+		uid = DefaultUID
+	}
+
+	privKey, pubKey := PrivKeyFromBytes(sm2.P256Sm2(), priv)
+	curve := sm2.P256Sm2()
+	n := curve.Params().N
+	d := privKey.D
+
+	za, err := sm2.ZA(pubKey, uid)
+	if err != nil {
+		return nil, fmt.Errorf("gm: za digest failed: %v", err)
+	}
+	h := sm3.New()
+	h.Write(za)
+	h.Write(msg)
+	e := h.Sum(nil)
+
+	dOctets := int2octets(d)
+	eOctets := bits2octets(e, n)
+
+	hlen := sm3DigestLength
+	v := bytes.Repeat([]byte{0x01}, hlen)
+	k := bytes.Repeat([]byte{0x00}, hlen)
+
+	k = hmacSM3(k, concat(v, []byte{0x00}, dOctets, eOctets))
+	v = hmacSM3(k, v)
+	k = hmacSM3(k, concat(v, []byte{0x01}, dOctets, eOctets))
+	v = hmacSM3(k, v)
+
+	for {
+		var t []byte
+		for len(t) < hlen {
+			v = hmacSM3(k, v)
+			t = append(t, v...)
+		}
+
+		kCandidate := new(big.Int).SetBytes(t[:hlen])
+		if kCandidate.Sign() == 0 || kCandidate.Cmp(n) >= 0 {
+			k = hmacSM3(k, append(append([]byte{}, v...), 0x00))
+			v = hmacSM3(k, v)
+			continue
+		}
+
+		x1, _ := curve.ScalarBaseMult(kCandidate.Bytes())
+		r := new(big.Int).Add(new(big.Int).SetBytes(e), x1)
+		r.Mod(r, n)
+		if r.Sign() == 0 || new(big.Int).Add(r, kCandidate).Cmp(n) == 0 {
+			k = hmacSM3(k, append(append([]byte{}, v...), 0x00))
+			v = hmacSM3(k, v)
+			continue
+		}
+
+		dPlus1Inv := new(big.Int).ModInverse(new(big.Int).Add(d, big.NewInt(1)), n)
+		s := new(big.Int).Sub(kCandidate, new(big.Int).Mul(r, d))
+		s.Mod(s, n)
+		s.Mul(s, dPlus1Inv)
+		s.Mod(s, n)
+		if s.Sign() == 0 {
+			k = hmacSM3(k, append(append([]byte{}, v...), 0x00))
+			v = hmacSM3(k, v)
+			continue
+		}
+
+		return SerializeSignature(r, s), nil
+	}
+}
+
+func concat(parts ...[]byte) []byte {
+	out := make([]byte, 0)
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+//SM2Signer 将原始 SM2 私钥适配为标准库 crypto.Signer 接口。digest 被当作待签名的原始
+//消息处理（而非预先计算的哈希），因为 SM2 签名需要 ZA||msg 一并参与 SM3 摘要。注意：Go 标准库的
+//x509.CreateCertificate/crypto/tls 目前只识别 RSA、ECDSA、Ed25519 公钥，会直接拒绝这里
+//Public() 返回的 *sm2.PublicKey，因此 SM2Signer 只适用于自定义的 crypto.Signer 消费者。
+type SM2Signer struct {
+	PrivateKey []byte // 32-byte raw SM2 private key
+	UID        []byte
+}
+
+//Public 返回与私钥对应的 *sm2.PublicKey
+func (s *SM2Signer) Public() crypto.PublicKey {
+	_, pub := PrivKeyFromBytes(sm2.P256Sm2(), s.PrivateKey)
+	return pub
+}
+
+//Sign 实现 crypto.Signer；rand 与 opts 被忽略，签名随机数由本包 SM2Sign 内部决定
+func (s *SM2Signer) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return SM2Sign(digest, s.PrivateKey, s.UID)
+}
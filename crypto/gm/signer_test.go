@@ -0,0 +1,65 @@
+package gm
+
+import (
+	"crypto"
+	"testing"
+
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+func TestSM2SignDeterministicIsStableAndVerifies(t *testing.T) {
+	priv, pub := GenerateKey()
+	msg := []byte("deterministic signing test")
+
+	sig1, err := SM2SignDeterministic(msg, priv, nil)
+	if err != nil {
+		t.Fatalf("SM2SignDeterministic failed: %v", err)
+	}
+	sig2, err := SM2SignDeterministic(msg, priv, nil)
+	if err != nil {
+		t.Fatalf("SM2SignDeterministic failed: %v", err)
+	}
+
+	if string(sig1) != string(sig2) {
+		t.Fatalf("expected identical signatures for the same (priv, msg), got different bytes")
+	}
+	if !SM2Verify(pub, msg, nil, sig1) {
+		t.Fatalf("deterministic signature did not verify")
+	}
+}
+
+func TestSM2SignDeterministicDiffersAcrossMessages(t *testing.T) {
+	priv, _ := GenerateKey()
+
+	sig1, _ := SM2SignDeterministic([]byte("message one"), priv, nil)
+	sig2, _ := SM2SignDeterministic([]byte("message two"), priv, nil)
+
+	if string(sig1) == string(sig2) {
+		t.Fatalf("expected different signatures for different messages")
+	}
+}
+
+func TestSM2SignerImplementsCryptoSigner(t *testing.T) {
+	priv, pub := GenerateKey()
+	signer := &SM2Signer{PrivateKey: priv}
+
+	var _ crypto.Signer = signer
+
+	msg := []byte("signer test message")
+	sig, err := signer.Sign(nil, msg, crypto.Hash(0))
+	if err != nil {
+		t.Fatalf("SM2Signer.Sign failed: %v", err)
+	}
+
+	if !SM2Verify(pub, msg, nil, sig) {
+		t.Fatalf("signature produced by SM2Signer did not verify")
+	}
+
+	pubKey, ok := signer.Public().(*sm2.PublicKey)
+	if !ok {
+		t.Fatalf("SM2Signer.Public() returned unexpected type %T", signer.Public())
+	}
+	if string(SerializePublicKey(pubKey)) != string(pub) {
+		t.Fatalf("SM2Signer.Public() did not match the expected public key")
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 const (
 	SM2PrivateKeyLength = 32
+	SM2PublicKeyLength  = 33
 )
 
 var	DefaultUID = []byte{0x31, 0x32, 0x33, 0x34, 0x35, 0x36, 0x37, 0x38, 0x31, 0x32, 0x33, 0x34, 0x35, 0x36, 0x37, 0x38}
@@ -40,8 +41,23 @@ func PrivKeyFromBytes(curve elliptic.Curve, pk []byte) (*sm2.PrivateKey, *sm2.Pu
 	return priv, &priv.PublicKey
 }
 
-func parsePubKey(pubKeyStr []byte) (key *sm2.PublicKey) {
-	return sm2.Decompress(pubKeyStr)
+//parsePubKeySafe 解析压缩公钥，对长度不符或 Decompress 内部 panic(如 X 无模平方根)的畸形输入返回 error 而非崩溃
+func parsePubKeySafe(pubKeyStr []byte) (pub *sm2.PublicKey, err error) {
+	if len(pubKeyStr) != SM2PublicKeyLength {
+		return nil, fmt.Errorf("gm: public key must be %d bytes, got %d", SM2PublicKeyLength, len(pubKeyStr))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			pub, err = nil, fmt.Errorf("gm: malformed public key: %v", r)
+		}
+	}()
+
+	key := sm2.Decompress(pubKeyStr)
+	if key == nil {
+		return nil, fmt.Errorf("gm: malformed public key")
+	}
+	return key, nil
 }
 
 //SerializePublicKey 公钥序列化
@@ -138,26 +154,26 @@ func SM2Verify(publicKey []byte, msg []byte, uid []byte, sig []byte) bool {
 		uid = DefaultUID
 	}
 
-	pub := parsePubKey(publicKey[:])
+	pub, err := parsePubKeySafe(publicKey)
+	if err != nil {
+		return false
+	}
 	r, s, err := DeserializeSignature(sig)
 	if err != nil {
-		fmt.Errorf("unmarshal sign failed:"+err.Error())
 		return false
 	}
 
 	return sm2.Sm2Verify(pub, msg, uid, r, s)
 }
 
+//SM2Encrypt 使用默认的原始 C1C3C2 拼接格式加密，兼容历史行为
 func SM2Encrypt(publicKey []byte, data []byte) ([]byte, error) {
-	pub := parsePubKey(publicKey[:])
-
-	return sm2.Encrypt(pub, data)
+	return SM2EncryptWithMode(publicKey, data, ModeRawC1C3C2)
 }
 
+//SM2Decrypt 使用默认的原始 C1C3C2 拼接格式解密，兼容历史行为
 func SM2Decrypt(privateKey []byte, data []byte) ([]byte, error) {
-	priv, _ := PrivKeyFromBytes(sm2.P256Sm2(), privateKey)
-
-	return sm2.Decrypt(priv, data)
+	return SM2DecryptWithMode(privateKey, data, ModeRawC1C3C2)
 }
 
 func PubKeyFromPrivate(privKey []byte) []byte {